@@ -0,0 +1,517 @@
+package fastly
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// ErrMissingStoreID is returned by operations that require a StoreID but
+// none was provided.
+var ErrMissingStoreID = errors.New("fastly: missing required field 'StoreID'")
+
+// ErrMissingKey is returned by operations that require a Key but none was
+// provided.
+var ErrMissingKey = errors.New("fastly: missing required field 'Key'")
+
+// ErrMissingBody is returned by operations that require a request body but
+// none was provided.
+var ErrMissingBody = errors.New("fastly: missing required field 'Body'")
+
+// ErrMissingName is returned by operations that require a Name but none was
+// provided.
+var ErrMissingName = errors.New("fastly: missing required field 'Name'")
+
+// Consistency controls the read consistency used for KV store operations.
+type Consistency string
+
+const (
+	// ConsistencyEventual allows a read to be served from a replica and may
+	// not reflect the most recent writes. This is the default and has
+	// lower latency than ConsistencyStrong.
+	ConsistencyEventual Consistency = "eventual"
+	// ConsistencyStrong always serves a read from the primary, guaranteeing
+	// the most recent writes are visible at the cost of higher latency.
+	ConsistencyStrong Consistency = "strong"
+)
+
+// KVStore is the API response structure for a single KV store.
+type KVStore struct {
+	StoreID  string `json:"id"`
+	Name     string `json:"name"`
+	Location string `json:"location,omitempty"`
+}
+
+// CreateKVStoreInput is used as input to the CreateKVStore function.
+type CreateKVStoreInput struct {
+	// Name is the name of the KV store to create (required).
+	Name string `json:"name"`
+	// Location is the region in which to provision the store (optional).
+	Location string `json:"location,omitempty"`
+}
+
+// CreateKVStore creates a new KV store.
+func (c *Client) CreateKVStore(i *CreateKVStoreInput) (*KVStore, error) {
+	if i.Name == "" {
+		return nil, ErrMissingName
+	}
+
+	resp, err := c.PostJSON("/resources/stores/kv", i, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var kvStore *KVStore
+	if err := json.NewDecoder(resp.Body).Decode(&kvStore); err != nil {
+		return nil, err
+	}
+	return kvStore, nil
+}
+
+// GetKVStoreInput is used as input to the GetKVStore function.
+type GetKVStoreInput struct {
+	// StoreID is the ID of the KV store to fetch (required).
+	StoreID string
+}
+
+// GetKVStore retrieves a single KV store by ID.
+func (c *Client) GetKVStore(i *GetKVStoreInput) (*KVStore, error) {
+	if i.StoreID == "" {
+		return nil, ErrMissingStoreID
+	}
+
+	path := fmt.Sprintf("/resources/stores/kv/%s", i.StoreID)
+	resp, err := c.Get(path, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var kvStore *KVStore
+	if err := json.NewDecoder(resp.Body).Decode(&kvStore); err != nil {
+		return nil, err
+	}
+	return kvStore, nil
+}
+
+// DeleteKVStoreInput is used as input to the DeleteKVStore function.
+type DeleteKVStoreInput struct {
+	// StoreID is the ID of the KV store to delete (required).
+	StoreID string
+}
+
+// DeleteKVStore deletes a KV store. The store must be empty before it can
+// be deleted.
+func (c *Client) DeleteKVStore(i *DeleteKVStoreInput) error {
+	if i.StoreID == "" {
+		return ErrMissingStoreID
+	}
+
+	path := fmt.Sprintf("/resources/stores/kv/%s", i.StoreID)
+	resp, err := c.Delete(path, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	return nil
+}
+
+// ListKVStoresInput is used as input to the ListKVStores function.
+type ListKVStoresInput struct {
+	// Cursor is used for paginating through results.
+	Cursor string
+	// Limit is the maximum number of stores to return per page.
+	Limit int
+}
+
+// ListKVStoresResponse is the response returned by ListKVStores.
+type ListKVStoresResponse struct {
+	Data []*KVStore
+	Meta map[string]string
+}
+
+// ListKVStores retrieves all KV stores.
+func (c *Client) ListKVStores(i *ListKVStoresInput) (*ListKVStoresResponse, error) {
+	ro := &RequestOptions{Params: map[string]string{}}
+	if i != nil {
+		if i.Cursor != "" {
+			ro.Params["cursor"] = i.Cursor
+		}
+		if i.Limit > 0 {
+			ro.Params["limit"] = strconv.Itoa(i.Limit)
+		}
+	}
+
+	resp, err := c.Get("/resources/stores/kv", ro)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var lr *ListKVStoresResponse
+	if err := json.NewDecoder(resp.Body).Decode(&lr); err != nil {
+		return nil, err
+	}
+	return lr, nil
+}
+
+// InsertKVStoreKeyInput is used as input to the InsertKVStoreKey function.
+type InsertKVStoreKeyInput struct {
+	// StoreID is the ID of the KV store to write to (required).
+	StoreID string
+	// Key is the key to write (required).
+	Key string
+	// Value is the value to store under Key (required).
+	Value string
+	// IfGenerationMatch, if set, makes the write conditional on the key
+	// still being at this generation.
+	IfGenerationMatch string
+}
+
+// InsertKVStoreKey inserts or overwrites a key in a KV store.
+func (c *Client) InsertKVStoreKey(i *InsertKVStoreKeyInput) error {
+	if i.StoreID == "" {
+		return ErrMissingStoreID
+	}
+	if i.Key == "" {
+		return ErrMissingKey
+	}
+
+	path := fmt.Sprintf("/resources/stores/kv/%s/keys/%s", i.StoreID, i.Key)
+	ro := &RequestOptions{Body: strings.NewReader(i.Value)}
+	if i.IfGenerationMatch != "" {
+		ro.Headers = map[string]string{"If-Generation-Match": i.IfGenerationMatch}
+	}
+
+	resp, err := c.Put(path, ro)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	return nil
+}
+
+// GetKVStoreKeyInput is used as input to the GetKVStoreKey and
+// GetKVStoreKeyWithGeneration functions.
+type GetKVStoreKeyInput struct {
+	// StoreID is the ID of the KV store to read from (required).
+	StoreID string
+	// Key is the key to fetch (required).
+	Key string
+	// IfNoneMatch, if set, makes GetKVStoreKeyWithGeneration conditional:
+	// if the key is still at this generation, the store returns 304 and
+	// GetKVStoreKeyGeneration.NotModified is true instead of the value
+	// being re-sent. Ignored by GetKVStoreKey.
+	IfNoneMatch string
+}
+
+// GetKVStoreKey retrieves the value stored under Key.
+func (c *Client) GetKVStoreKey(i *GetKVStoreKeyInput) (string, error) {
+	if i.StoreID == "" {
+		return "", ErrMissingStoreID
+	}
+	if i.Key == "" {
+		return "", ErrMissingKey
+	}
+
+	path := fmt.Sprintf("/resources/stores/kv/%s/keys/%s", i.StoreID, i.Key)
+	resp, err := c.Get(path, nil)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+// GetKVStoreKeyGeneration is returned by GetKVStoreKeyWithGeneration,
+// pairing a key's value with the generation it was read at.
+type GetKVStoreKeyGeneration struct {
+	// Value is the key's value. Empty when NotModified is true.
+	Value string
+	// Generation identifies the version of the value that was read. Pass
+	// it back as GetKVStoreKeyInput.IfNoneMatch on a later call to avoid
+	// re-fetching the value if it hasn't changed.
+	Generation string
+	// NotModified is true when IfNoneMatch was set and the key is still
+	// at that generation, so Value was not re-sent.
+	NotModified bool
+}
+
+// GetKVStoreKeyWithGeneration behaves like GetKVStoreKey but additionally
+// returns the generation the value was read at, and supports a conditional
+// read via i.IfNoneMatch.
+func (c *Client) GetKVStoreKeyWithGeneration(i *GetKVStoreKeyInput) (*GetKVStoreKeyGeneration, error) {
+	if i.StoreID == "" {
+		return nil, ErrMissingStoreID
+	}
+	if i.Key == "" {
+		return nil, ErrMissingKey
+	}
+
+	path := fmt.Sprintf("/resources/stores/kv/%s/keys/%s", i.StoreID, i.Key)
+	ro := &RequestOptions{}
+	if i.IfNoneMatch != "" {
+		ro.Headers = map[string]string{"If-None-Match": i.IfNoneMatch}
+	}
+
+	resp, err := c.Get(path, ro)
+	if err != nil {
+		if isNotModified(err) {
+			return &GetKVStoreKeyGeneration{Generation: i.IfNoneMatch, NotModified: true}, nil
+		}
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	return &GetKVStoreKeyGeneration{
+		Value:      string(b),
+		Generation: resp.Header.Get("ETag"),
+	}, nil
+}
+
+// isNotModified reports whether err represents a 304 Not Modified response
+// to a conditional GetKVStoreKeyWithGeneration request.
+func isNotModified(err error) bool {
+	var httpErr *HTTPError
+	if errors.As(err, &httpErr) {
+		return httpErr.StatusCode == 304
+	}
+	return false
+}
+
+// DeleteKVStoreKeyInput is used as input to the DeleteKVStoreKey function.
+type DeleteKVStoreKeyInput struct {
+	// StoreID is the ID of the KV store to delete from (required).
+	StoreID string
+	// Key is the key to delete (required).
+	Key string
+	// IfGenerationMatch, if set, makes the delete conditional on the key
+	// still being at this generation.
+	IfGenerationMatch string
+}
+
+// DeleteKVStoreKey deletes a key from a KV store.
+func (c *Client) DeleteKVStoreKey(i *DeleteKVStoreKeyInput) error {
+	if i.StoreID == "" {
+		return ErrMissingStoreID
+	}
+	if i.Key == "" {
+		return ErrMissingKey
+	}
+
+	path := fmt.Sprintf("/resources/stores/kv/%s/keys/%s", i.StoreID, i.Key)
+	ro := &RequestOptions{}
+	if i.IfGenerationMatch != "" {
+		ro.Headers = map[string]string{"If-Generation-Match": i.IfGenerationMatch}
+	}
+
+	resp, err := c.Delete(path, ro)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	return nil
+}
+
+// BatchModifyKVStoreKeyInput is used as input to the BatchModifyKVStoreKey
+// function.
+type BatchModifyKVStoreKeyInput struct {
+	// StoreID is the ID of the KV store to modify (required).
+	StoreID string
+	// Body is a stream of newline-delimited JSON batch operations
+	// (required).
+	Body io.Reader
+}
+
+// BatchModifyKVStoreKey applies a stream of newline-delimited JSON batch
+// operations to a KV store.
+func (c *Client) BatchModifyKVStoreKey(i *BatchModifyKVStoreKeyInput) error {
+	if i.StoreID == "" {
+		return ErrMissingStoreID
+	}
+	if i.Body == nil {
+		return ErrMissingBody
+	}
+
+	path := fmt.Sprintf("/resources/stores/kv/%s/batch", i.StoreID)
+	ro := &RequestOptions{
+		Body:    i.Body,
+		Headers: map[string]string{"Content-Type": "application/x-ndjson"},
+	}
+
+	resp, err := c.Put(path, ro)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	return nil
+}
+
+// ListKVStoreKeysInput is used as input to the ListKVStoreKeys function and
+// to NewListKVStoreKeysPaginator.
+type ListKVStoreKeysInput struct {
+	// StoreID is the ID of the KV store to list keys from (required).
+	StoreID string
+	// Consistency controls the read consistency of the listing.
+	Consistency Consistency
+	// Cursor is used for paginating through results.
+	Cursor string
+	// Limit is the maximum number of keys to return per page.
+	Limit int
+	// Prefix, if set, restricts the listing to keys starting with Prefix.
+	Prefix string
+	// Separator, if set, collapses the listing to distinct "directory"
+	// entries: for each key that starts with Prefix, the substring up to
+	// and including the first occurrence of Separator after the prefix is
+	// returned (deduplicated) in ListKVStoreKeysResponse.Groups. Data still
+	// holds the page's keys as listed.
+	Separator string
+}
+
+// ListKVStoreKeysResponse is the response returned by ListKVStoreKeys.
+type ListKVStoreKeysResponse struct {
+	Data []string
+	// Groups holds the deduplicated "directory" entries produced when
+	// ListKVStoreKeysInput.Separator is set. It is empty otherwise.
+	Groups []string
+	Meta   map[string]string
+}
+
+// ListKVStoreKeys lists the keys in a KV store.
+func (c *Client) ListKVStoreKeys(i *ListKVStoreKeysInput) (*ListKVStoreKeysResponse, error) {
+	if i.StoreID == "" {
+		return nil, ErrMissingStoreID
+	}
+
+	ro := &RequestOptions{Params: map[string]string{}}
+	if i.Consistency != "" {
+		ro.Params["consistency"] = string(i.Consistency)
+	}
+	if i.Cursor != "" {
+		ro.Params["cursor"] = i.Cursor
+	}
+	if i.Limit > 0 {
+		ro.Params["limit"] = strconv.Itoa(i.Limit)
+	}
+	if i.Prefix != "" {
+		ro.Params["prefix"] = i.Prefix
+	}
+
+	path := fmt.Sprintf("/resources/stores/kv/%s/keys", i.StoreID)
+	resp, err := c.Get(path, ro)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var lr *ListKVStoreKeysResponse
+	if err := json.NewDecoder(resp.Body).Decode(&lr); err != nil {
+		return nil, err
+	}
+
+	if i.Separator != "" {
+		lr.Groups = foldKeyGroups(i.Prefix, i.Separator, lr.Data, make(map[string]struct{}), nil)
+	}
+
+	return lr, nil
+}
+
+// foldKeyGroups collapses keys into deduplicated "directory" entries,
+// preserving the order each entry was first seen. seen is mutated as a
+// running dedup set so callers can fold multiple pages in sequence.
+func foldKeyGroups(prefix, separator string, keys []string, seen map[string]struct{}, groups []string) []string {
+	for _, key := range keys {
+		if !strings.HasPrefix(key, prefix) {
+			continue
+		}
+		rest := key[len(prefix):]
+		group := key
+		if idx := strings.Index(rest, separator); idx != -1 {
+			group = prefix + rest[:idx+len(separator)]
+		}
+		if _, ok := seen[group]; ok {
+			continue
+		}
+		seen[group] = struct{}{}
+		groups = append(groups, group)
+	}
+	return groups
+}
+
+// ListKVStoreKeysPaginator paginates through the keys of a KV store. Use
+// NewListKVStoreKeysPaginator to construct one.
+type ListKVStoreKeysPaginator struct {
+	client     *Client
+	consumed   bool
+	input      *ListKVStoreKeysInput
+	keys       []string
+	err        error
+	nextCursor string
+	seen       map[string]struct{}
+	groups     []string
+}
+
+// NewListKVStoreKeysPaginator returns a new paginator for the keys of a KV
+// store, honoring i.Prefix and i.Separator exactly as ListKVStoreKeys does.
+func (c *Client) NewListKVStoreKeysPaginator(i *ListKVStoreKeysInput) *ListKVStoreKeysPaginator {
+	return &ListKVStoreKeysPaginator{
+		client: c,
+		input:  i,
+		seen:   make(map[string]struct{}),
+	}
+}
+
+// Next advances the paginator to the next page, returning false once there
+// are no more pages or an error occurs.
+func (p *ListKVStoreKeysPaginator) Next() bool {
+	if p.consumed && p.nextCursor == "" {
+		return false
+	}
+
+	input := *p.input
+	input.Cursor = p.nextCursor
+	resp, err := p.client.ListKVStoreKeys(&input)
+	if err != nil {
+		p.err = err
+		return false
+	}
+
+	p.keys = resp.Data
+	p.consumed = true
+	p.nextCursor = resp.Meta["next_cursor"]
+
+	if p.input.Separator != "" {
+		p.groups = foldKeyGroups(p.input.Prefix, p.input.Separator, p.keys, p.seen, p.groups)
+	}
+
+	return true
+}
+
+// Keys returns the keys fetched by the most recent call to Next.
+func (p *ListKVStoreKeysPaginator) Keys() []string {
+	return p.keys
+}
+
+// Groups returns the deduplicated "directory" entries folded so far, across
+// every page consumed, when ListKVStoreKeysInput.Separator is set.
+func (p *ListKVStoreKeysPaginator) Groups() []string {
+	return p.groups
+}
+
+// Err returns the first error, if any, encountered while paginating.
+func (p *ListKVStoreKeysPaginator) Err() error {
+	return p.err
+}
@@ -0,0 +1,221 @@
+package fastly
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"io"
+)
+
+// kvBatchMaxEntries and kvBatchMaxBytes are the documented per-request
+// limits for BatchModifyKVStoreKey. KVBatch chunks automatically at these
+// boundaries, issuing additional requests transparently.
+const (
+	kvBatchMaxEntries = 1000
+	kvBatchMaxBytes   = 16 * 1024 * 1024 // 16MiB
+)
+
+// kvBatchOp is the operation a KVBatch entry performs.
+type kvBatchOp string
+
+const (
+	kvBatchOpInsert kvBatchOp = "insert"
+	kvBatchOpUpsert kvBatchOp = "upsert"
+	kvBatchOpDelete kvBatchOp = "delete"
+	kvBatchOpAppend kvBatchOp = "append"
+)
+
+// kvBatchRecord is the NDJSON record shape sent to BatchModifyKVStoreKey.
+type kvBatchRecord struct {
+	Key               string    `json:"key"`
+	Operation         kvBatchOp `json:"operation,omitempty"`
+	Value             string    `json:"value,omitempty"`
+	IfGenerationMatch string    `json:"if_generation_match,omitempty"`
+	Metadata          string    `json:"metadata,omitempty"`
+	TTL               int       `json:"time_to_live_sec,omitempty"`
+	BackgroundFetch   bool      `json:"background_fetch,omitempty"`
+}
+
+// BatchOpt configures a single KVBatch entry.
+type BatchOpt func(*kvBatchRecord)
+
+// WithIfGenerationMatch makes the entry's write conditional on the key still
+// being at generation.
+func WithIfGenerationMatch(generation string) BatchOpt {
+	return func(r *kvBatchRecord) { r.IfGenerationMatch = generation }
+}
+
+// WithMetadata attaches opaque metadata to the entry.
+func WithMetadata(metadata string) BatchOpt {
+	return func(r *kvBatchRecord) { r.Metadata = metadata }
+}
+
+// WithEntryTTL sets the entry's time to live, in seconds.
+func WithEntryTTL(seconds int) BatchOpt {
+	return func(r *kvBatchRecord) { r.TTL = seconds }
+}
+
+// WithBackgroundFetch marks the entry to be applied asynchronously.
+func WithBackgroundFetch() BatchOpt {
+	return func(r *kvBatchRecord) { r.BackgroundFetch = true }
+}
+
+// BatchKeyResult is the outcome of a single key within a KVBatch.
+//
+// BatchModifyKVStoreKey applies each chunk atomically and reports only a
+// single chunk-level success or failure, with no per-key detail in the
+// response body. Error is therefore the chunk's error (or nil) applied to
+// every key that chunk contained, not an independently parsed per-key
+// outcome; a failed BatchKeyResult means "this key was in a chunk that
+// failed," not "this specific key was rejected."
+type BatchKeyResult struct {
+	Key   string
+	Error error
+}
+
+// BatchResult is returned by KVBatch.Do and reports, for every key
+// submitted, the outcome of the chunk it was sent in. See BatchKeyResult
+// for the granularity this actually reflects.
+type BatchResult struct {
+	// Results holds one entry per key in every chunk that was sent, in
+	// submission order. Do stops at the first chunk that fails, so keys in
+	// later chunks are not sent and have no entry.
+	Results []BatchKeyResult
+}
+
+// Errors returns the keys that failed, if any.
+func (r *BatchResult) Errors() []BatchKeyResult {
+	var failed []BatchKeyResult
+	for _, res := range r.Results {
+		if res.Error != nil {
+			failed = append(failed, res)
+		}
+	}
+	return failed
+}
+
+// KVBatch builds a batch of KV store mutations and submits them to
+// BatchModifyKVStoreKey, chunking automatically at the documented
+// per-request size and count limits and streaming each chunk through an
+// io.Pipe rather than buffering it in full.
+type KVBatch struct {
+	StoreID string
+
+	records []kvBatchRecord
+}
+
+// NewKVBatch returns an empty KVBatch for the given store.
+func NewKVBatch(storeID string) *KVBatch {
+	return &KVBatch{StoreID: storeID}
+}
+
+// Insert adds a create-only write of key/value to the batch.
+func (b *KVBatch) Insert(key string, value []byte) *KVBatch {
+	return b.add(key, value, kvBatchOpInsert)
+}
+
+// Upsert adds a create-or-overwrite write of key/value to the batch.
+func (b *KVBatch) Upsert(key string, value []byte, opts ...BatchOpt) *KVBatch {
+	return b.add(key, value, kvBatchOpUpsert, opts...)
+}
+
+// Delete adds a deletion of key to the batch.
+func (b *KVBatch) Delete(key string) *KVBatch {
+	return b.add(key, nil, kvBatchOpDelete)
+}
+
+// Append adds a value to the end of the existing value stored at key.
+func (b *KVBatch) Append(key string, value []byte) *KVBatch {
+	return b.add(key, value, kvBatchOpAppend)
+}
+
+func (b *KVBatch) add(key string, value []byte, op kvBatchOp, opts ...BatchOpt) *KVBatch {
+	rec := kvBatchRecord{
+		Key:       key,
+		Operation: op,
+		Value:     base64.StdEncoding.EncodeToString(value),
+	}
+	for _, opt := range opts {
+		opt(&rec)
+	}
+	b.records = append(b.records, rec)
+	return b
+}
+
+// Do submits the batch, issuing as many chunked requests as required by the
+// documented per-request size/count limits, and returns the outcome of
+// every key's chunk (see BatchKeyResult). It stops at the first chunk that
+// fails, returning the results gathered so far along with that error.
+func (b *KVBatch) Do(c *Client) (*BatchResult, error) {
+	if b.StoreID == "" {
+		return nil, ErrMissingStoreID
+	}
+	if len(b.records) == 0 {
+		return &BatchResult{}, nil
+	}
+
+	result := &BatchResult{}
+	for _, chunk := range chunkKVBatch(b.records, kvBatchMaxEntries, kvBatchMaxBytes) {
+		chunkResult, err := b.submitChunk(c, chunk)
+		result.Results = append(result.Results, chunkResult...)
+		if err != nil {
+			return result, err
+		}
+	}
+	return result, nil
+}
+
+// submitChunk streams chunk as NDJSON through an io.Pipe into
+// BatchModifyKVStoreKey, so the full batch is never buffered in memory.
+// BatchModifyKVStoreKey applies a chunk atomically and its response carries
+// no per-key detail, so the chunk's single error (or nil) is reported for
+// every key the chunk contained.
+func (b *KVBatch) submitChunk(c *Client, chunk []kvBatchRecord) ([]BatchKeyResult, error) {
+	pr, pw := io.Pipe()
+
+	go func() {
+		enc := json.NewEncoder(pw)
+		for _, rec := range chunk {
+			if err := enc.Encode(rec); err != nil {
+				pw.CloseWithError(err)
+				return
+			}
+		}
+		pw.Close()
+	}()
+
+	err := c.BatchModifyKVStoreKey(&BatchModifyKVStoreKeyInput{StoreID: b.StoreID, Body: pr})
+
+	results := make([]BatchKeyResult, len(chunk))
+	for i, rec := range chunk {
+		results[i] = BatchKeyResult{Key: rec.Key, Error: err}
+	}
+	return results, err
+}
+
+// chunkKVBatch splits records into chunks that each satisfy maxEntries and
+// an approximate maxBytes budget.
+func chunkKVBatch(records []kvBatchRecord, maxEntries, maxBytes int) [][]kvBatchRecord {
+	var chunks [][]kvBatchRecord
+	var current []kvBatchRecord
+	currentBytes := 0
+
+	flush := func() {
+		if len(current) > 0 {
+			chunks = append(chunks, current)
+			current = nil
+			currentBytes = 0
+		}
+	}
+
+	for _, rec := range records {
+		size := len(rec.Key) + len(rec.Value) + len(rec.Metadata) + 1
+		if len(current) >= maxEntries || (currentBytes+size > maxBytes && len(current) > 0) {
+			flush()
+		}
+		current = append(current, rec)
+		currentBytes += size
+	}
+	flush()
+
+	return chunks
+}
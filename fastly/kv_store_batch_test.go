@@ -0,0 +1,52 @@
+package fastly
+
+import "testing"
+
+func TestKVBatch_Do(t *testing.T) {
+	t.Parallel()
+
+	const storeID = "kv-batch-test-store"
+
+	batch := NewKVBatch(storeID).
+		Insert("apple", []byte("fuji")).
+		Upsert("banana", []byte("cavendish"), WithEntryTTL(3600)).
+		Append("carrot", []byte("nantes")).
+		Delete("old-key")
+
+	var result *BatchResult
+	var err error
+	Record(t, "kv_store/batch-do", func(c *Client) {
+		result, err = batch.Do(c)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got, want := len(result.Results), 4; got != want {
+		t.Fatalf("BatchResult.Results: got %d entries, want %d", got, want)
+	}
+	for _, res := range result.Results {
+		if res.Error != nil {
+			t.Errorf("key %q: unexpected error: %v", res.Key, res.Error)
+		}
+	}
+}
+
+func TestKVBatch_chunkKVBatch(t *testing.T) {
+	t.Parallel()
+
+	records := make([]kvBatchRecord, 5)
+	for i := range records {
+		records[i] = kvBatchRecord{Key: "k", Value: "v"}
+	}
+
+	chunks := chunkKVBatch(records, 2, 1<<20)
+	if got, want := len(chunks), 3; got != want {
+		t.Fatalf("chunkKVBatch: got %d chunks, want %d", got, want)
+	}
+	for i, want := range []int{2, 2, 1} {
+		if got := len(chunks[i]); got != want {
+			t.Errorf("chunk %d: got %d records, want %d", i, got, want)
+		}
+	}
+}
@@ -0,0 +1,301 @@
+package fastly
+
+import (
+	"container/list"
+	"errors"
+	"sync"
+	"time"
+)
+
+// CacheStats reports cumulative counters for a CachingKVClient.
+type CacheStats struct {
+	Hits      uint64
+	Misses    uint64
+	Evictions uint64
+}
+
+// cacheKey identifies a cached entry by store and key.
+type cacheKey struct {
+	StoreID string
+	Key     string
+}
+
+// cacheEntry is the value stored in the cache's linked list.
+type cacheEntry struct {
+	key       cacheKey
+	value     string
+	notFound  bool
+	size      int
+	expiresAt time.Time
+}
+
+// CachingKVClient wraps a *Client with an in-memory, write-through LRU
+// cache for GetKVStoreKey/InsertKVStoreKey/DeleteKVStoreKey/
+// BatchModifyKVStoreKey. Mutations update or invalidate the cache
+// synchronously with the underlying API call, and a miss is cached
+// negatively (as NotFound) for NegativeTTL so repeated lookups of an
+// absent key don't all hit the API. Concurrent Get calls for the same
+// missing key are coalesced into a single request.
+//
+// The zero value is not usable; construct one with NewCachingKVClient.
+type CachingKVClient struct {
+	*Client
+
+	maxEntries int
+	maxBytes   int
+	ttl        time.Duration
+	negTTL     time.Duration
+
+	mu      sync.Mutex
+	entries map[cacheKey]*list.Element
+	order   *list.List // front = most recently used
+	bytes   int
+	stats   CacheStats
+
+	flightMu sync.Mutex
+	flight   map[cacheKey]*kvCacheCall
+}
+
+// kvCacheCall represents an in-flight GetKVStoreKey call that other callers
+// for the same key can wait on instead of issuing a redundant request.
+type kvCacheCall struct {
+	done  chan struct{}
+	value string
+	err   error
+}
+
+// CachingKVClientOption configures a CachingKVClient constructed with
+// NewCachingKVClient.
+type CachingKVClientOption func(*CachingKVClient)
+
+// WithMaxEntries caps the number of cached entries. The default is 10000.
+func WithMaxEntries(n int) CachingKVClientOption {
+	return func(c *CachingKVClient) { c.maxEntries = n }
+}
+
+// WithMaxBytes caps the total size, in bytes, of cached entries, each
+// counted as its store ID, key and value lengths. The default is 64MiB.
+func WithMaxBytes(n int) CachingKVClientOption {
+	return func(c *CachingKVClient) { c.maxBytes = n }
+}
+
+// WithTTL sets how long a positive cache entry remains valid. The default
+// is 30 seconds.
+func WithTTL(d time.Duration) CachingKVClientOption {
+	return func(c *CachingKVClient) { c.ttl = d }
+}
+
+// WithNegativeTTL sets how long a NotFound result is cached. The default is
+// 5 seconds.
+func WithNegativeTTL(d time.Duration) CachingKVClientOption {
+	return func(c *CachingKVClient) { c.negTTL = d }
+}
+
+// NewCachingKVClient returns a CachingKVClient wrapping client.
+func NewCachingKVClient(client *Client, opts ...CachingKVClientOption) *CachingKVClient {
+	c := &CachingKVClient{
+		Client:     client,
+		maxEntries: 10000,
+		maxBytes:   64 * 1024 * 1024,
+		ttl:        30 * time.Second,
+		negTTL:     5 * time.Second,
+		entries:    make(map[cacheKey]*list.Element),
+		order:      list.New(),
+		flight:     make(map[cacheKey]*kvCacheCall),
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// Reset clears the cache and its stats counters.
+func (c *CachingKVClient) Reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries = make(map[cacheKey]*list.Element)
+	c.order = list.New()
+	c.bytes = 0
+	c.stats = CacheStats{}
+}
+
+// Stats returns a snapshot of the cache's hit/miss/eviction counters.
+func (c *CachingKVClient) Stats() CacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.stats
+}
+
+// GetKVStoreKey returns the cached value for i.Key if present and unexpired,
+// otherwise it fetches it from the underlying Client, caching the result
+// (including a NotFound miss). Concurrent calls for the same missing key
+// are coalesced into a single underlying request.
+func (c *CachingKVClient) GetKVStoreKey(i *GetKVStoreKeyInput) (string, error) {
+	key := cacheKey{StoreID: i.StoreID, Key: i.Key}
+
+	if value, notFound, ok := c.lookup(key); ok {
+		c.mu.Lock()
+		c.stats.Hits++
+		c.mu.Unlock()
+		if notFound {
+			return "", &HTTPError{StatusCode: 404}
+		}
+		return value, nil
+	}
+
+	c.mu.Lock()
+	c.stats.Misses++
+	c.mu.Unlock()
+
+	value, err := c.singleflight(key, func() (string, error) {
+		return c.Client.GetKVStoreKey(i)
+	})
+
+	if err != nil {
+		if isNotFound(err) {
+			c.store(key, "", true)
+		}
+		return "", err
+	}
+	c.store(key, value, false)
+	return value, nil
+}
+
+// InsertKVStoreKey writes through to the underlying Client and updates the
+// cache on success.
+func (c *CachingKVClient) InsertKVStoreKey(i *InsertKVStoreKeyInput) error {
+	if err := c.Client.InsertKVStoreKey(i); err != nil {
+		return err
+	}
+	c.store(cacheKey{StoreID: i.StoreID, Key: i.Key}, i.Value, false)
+	return nil
+}
+
+// DeleteKVStoreKey writes through to the underlying Client and invalidates
+// the cache entry on success.
+func (c *CachingKVClient) DeleteKVStoreKey(i *DeleteKVStoreKeyInput) error {
+	if err := c.Client.DeleteKVStoreKey(i); err != nil {
+		return err
+	}
+	c.invalidate(cacheKey{StoreID: i.StoreID, Key: i.Key})
+	return nil
+}
+
+// BatchModifyKVStoreKey writes through to the underlying Client. Since a
+// batch body can describe arbitrary keys, the affected store's entries are
+// invalidated wholesale rather than parsed out of the request body.
+func (c *CachingKVClient) BatchModifyKVStoreKey(i *BatchModifyKVStoreKeyInput) error {
+	if err := c.Client.BatchModifyKVStoreKey(i); err != nil {
+		return err
+	}
+	c.invalidateStore(i.StoreID)
+	return nil
+}
+
+func (c *CachingKVClient) lookup(key cacheKey) (value string, notFound bool, ok bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el, found := c.entries[key]
+	if !found {
+		return "", false, false
+	}
+	entry := el.Value.(*cacheEntry)
+	if time.Now().After(entry.expiresAt) {
+		c.removeLocked(el)
+		return "", false, false
+	}
+	c.order.MoveToFront(el)
+	return entry.value, entry.notFound, true
+}
+
+func (c *CachingKVClient) store(key cacheKey, value string, notFound bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if el, ok := c.entries[key]; ok {
+		c.removeLocked(el)
+	}
+
+	ttl := c.ttl
+	if notFound {
+		ttl = c.negTTL
+	}
+	entry := &cacheEntry{
+		key:       key,
+		value:     value,
+		notFound:  notFound,
+		size:      len(key.StoreID) + len(key.Key) + len(value),
+		expiresAt: time.Now().Add(ttl),
+	}
+
+	el := c.order.PushFront(entry)
+	c.entries[key] = el
+	c.bytes += entry.size
+
+	for (len(c.entries) > c.maxEntries || c.bytes > c.maxBytes) && c.order.Len() > 0 {
+		back := c.order.Back()
+		c.removeLocked(back)
+		c.stats.Evictions++
+	}
+}
+
+func (c *CachingKVClient) invalidate(key cacheKey) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if el, ok := c.entries[key]; ok {
+		c.removeLocked(el)
+	}
+}
+
+func (c *CachingKVClient) invalidateStore(storeID string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for key, el := range c.entries {
+		if key.StoreID == storeID {
+			c.removeLocked(el)
+		}
+	}
+}
+
+// removeLocked removes el from the cache. c.mu must be held.
+func (c *CachingKVClient) removeLocked(el *list.Element) {
+	entry := el.Value.(*cacheEntry)
+	delete(c.entries, entry.key)
+	c.order.Remove(el)
+	c.bytes -= entry.size
+}
+
+// singleflight ensures only one underlying GetKVStoreKey call is in flight
+// per key at a time; concurrent callers for the same key wait on and share
+// its result.
+func (c *CachingKVClient) singleflight(key cacheKey, fn func() (string, error)) (string, error) {
+	c.flightMu.Lock()
+	if call, ok := c.flight[key]; ok {
+		c.flightMu.Unlock()
+		<-call.done
+		return call.value, call.err
+	}
+
+	call := &kvCacheCall{done: make(chan struct{})}
+	c.flight[key] = call
+	c.flightMu.Unlock()
+
+	call.value, call.err = fn()
+	close(call.done)
+
+	c.flightMu.Lock()
+	delete(c.flight, key)
+	c.flightMu.Unlock()
+
+	return call.value, call.err
+}
+
+// isNotFound reports whether err represents a 404 from the KV store API.
+func isNotFound(err error) bool {
+	var httpErr *HTTPError
+	if errors.As(err, &httpErr) {
+		return httpErr.StatusCode == 404
+	}
+	return false
+}
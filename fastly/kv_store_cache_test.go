@@ -0,0 +1,120 @@
+package fastly
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+func TestCachingKVClient_HitMissEviction(t *testing.T) {
+	t.Parallel()
+
+	const storeID = "kv-cache-test-store"
+
+	var cached *CachingKVClient
+	Record(t, "kv_store/cache-get", func(c *Client) {
+		cached = NewCachingKVClient(c, WithMaxEntries(2))
+
+		got, err := cached.GetKVStoreKey(&GetKVStoreKeyInput{StoreID: storeID, Key: "apple"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != "fuji" {
+			t.Errorf("GetKVStoreKey: got %q, want %q", got, "fuji")
+		}
+
+		// second read for the same key should be served from the cache.
+		if _, err := cached.GetKVStoreKey(&GetKVStoreKeyInput{StoreID: storeID, Key: "apple"}); err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := cached.GetKVStoreKey(&GetKVStoreKeyInput{StoreID: storeID, Key: "banana"}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := cached.GetKVStoreKey(&GetKVStoreKeyInput{StoreID: storeID, Key: "carrot"}); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	stats := cached.Stats()
+	if stats.Hits != 1 {
+		t.Errorf("Stats.Hits: got %d, want 1", stats.Hits)
+	}
+	if stats.Misses != 3 {
+		t.Errorf("Stats.Misses: got %d, want 3", stats.Misses)
+	}
+	if stats.Evictions != 1 {
+		t.Errorf("Stats.Evictions: got %d, want 1 (maxEntries=2)", stats.Evictions)
+	}
+}
+
+func TestCachingKVClient_WriteThroughInvalidates(t *testing.T) {
+	t.Parallel()
+
+	const storeID = "kv-cache-test-store"
+
+	Record(t, "kv_store/cache-write-through", func(c *Client) {
+		cached := NewCachingKVClient(c)
+
+		if err := cached.InsertKVStoreKey(&InsertKVStoreKeyInput{StoreID: storeID, Key: "dragonfruit", Value: "v1"}); err != nil {
+			t.Fatal(err)
+		}
+		got, err := cached.GetKVStoreKey(&GetKVStoreKeyInput{StoreID: storeID, Key: "dragonfruit"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != "v1" {
+			t.Errorf("GetKVStoreKey after Insert: got %q, want %q", got, "v1")
+		}
+		if stats := cached.Stats(); stats.Misses != 0 {
+			t.Errorf("Stats.Misses: got %d, want 0 (write-through should populate the cache)", stats.Misses)
+		}
+
+		if err := cached.DeleteKVStoreKey(&DeleteKVStoreKeyInput{StoreID: storeID, Key: "dragonfruit"}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := cached.GetKVStoreKey(&GetKVStoreKeyInput{StoreID: storeID, Key: "dragonfruit"}); err == nil {
+			t.Error("GetKVStoreKey after Delete: expected an error, got nil")
+		}
+	})
+}
+
+// TestCachingKVClient_SingleflightCoalescesConcurrentMisses exercises
+// singleflight directly with a stub call so it can assert on the number of
+// underlying calls actually made, rather than on Stats.Misses, which is
+// incremented once per caller before singleflight runs and so would read
+// the same whether or not calls were coalesced.
+func TestCachingKVClient_SingleflightCoalescesConcurrentMisses(t *testing.T) {
+	t.Parallel()
+
+	cached := NewCachingKVClient(&Client{})
+	key := cacheKey{StoreID: "kv-cache-test-store", Key: "missing"}
+
+	var calls int64
+	started := make(chan struct{})
+	var startOnce sync.Once
+	release := make(chan struct{})
+
+	const n = 10
+	var wg sync.WaitGroup
+	wg.Add(n)
+	for i := 0; i < n; i++ {
+		go func() {
+			defer wg.Done()
+			_, _ = cached.singleflight(key, func() (string, error) {
+				atomic.AddInt64(&calls, 1)
+				startOnce.Do(func() { close(started) })
+				<-release
+				return "", &HTTPError{StatusCode: 404}
+			})
+		}()
+	}
+
+	<-started
+	close(release)
+	wg.Wait()
+
+	if got := atomic.LoadInt64(&calls); got != 1 {
+		t.Errorf("underlying call invoked %d times, want 1 (concurrent misses should coalesce into a single request)", got)
+	}
+}
@@ -0,0 +1,348 @@
+package fastly
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+// ErrCASMismatch is returned by PutKVObjectAtomic and DeleteKVObjectAtomic
+// when the generation supplied by the caller no longer matches the
+// generation stored in the KV store, meaning another writer has modified
+// the object since it was last read.
+var ErrCASMismatch = errors.New("fastly: KV object generation mismatch")
+
+// KVObject is implemented by types that can be stored in a KV store through
+// PutKVObject, GetKVObject, DeleteKVObject, and ListKVObjects. Index reports
+// the generation the object was loaded at (or 0 for a not-yet-persisted
+// object) and is used by the Atomic variants to populate
+// If-Generation-Match.
+type KVObject interface {
+	// Key is the KV store key the object is stored under.
+	Key() string
+	// Value encodes the object for storage.
+	Value() ([]byte, error)
+	// SetValue decodes a stored payload into the object.
+	SetValue([]byte) error
+	// Index returns the generation the object was last read at.
+	Index() uint64
+}
+
+// Codec encodes and decodes the payload produced by KVObject.Value/consumed
+// by KVObject.SetValue for storage. The default codec used when none is
+// supplied is JSONCodec.
+type Codec interface {
+	Marshal(v any) ([]byte, error)
+	Unmarshal(data []byte, v any) error
+}
+
+// JSONCodec encodes values as JSON. It is the default Codec used by
+// PutKVObject, GetKVObject, DeleteKVObject, and ListKVObjects.
+type JSONCodec struct{}
+
+// Marshal implements Codec.
+func (JSONCodec) Marshal(v any) ([]byte, error) { return json.Marshal(v) }
+
+// Unmarshal implements Codec.
+func (JSONCodec) Unmarshal(data []byte, v any) error { return json.Unmarshal(data, v) }
+
+// GobCodec encodes values using encoding/gob.
+type GobCodec struct{}
+
+// Marshal implements Codec.
+func (GobCodec) Marshal(v any) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Unmarshal implements Codec.
+func (GobCodec) Unmarshal(data []byte, v any) error {
+	return gob.NewDecoder(bytes.NewReader(data)).Decode(v)
+}
+
+// RawCodec stores and loads values as uninterpreted bytes, with no framing
+// of its own.
+type RawCodec struct{}
+
+// Marshal implements Codec.
+func (RawCodec) Marshal(v any) ([]byte, error) {
+	b, ok := v.([]byte)
+	if !ok {
+		return nil, fmt.Errorf("fastly: RawCodec requires a []byte, got %T", v)
+	}
+	return b, nil
+}
+
+// Unmarshal implements Codec.
+func (RawCodec) Unmarshal(data []byte, v any) error {
+	b, ok := v.(*[]byte)
+	if !ok {
+		return fmt.Errorf("fastly: RawCodec requires a *[]byte, got %T", v)
+	}
+	*b = data
+	return nil
+}
+
+// encodeObject encodes obj.Value()'s payload with codec (defaulting to
+// JSONCodec), producing the string stored under obj.Key().
+func encodeObject(codec Codec, obj KVObject) (string, error) {
+	if codec == nil {
+		codec = JSONCodec{}
+	}
+	payload, err := obj.Value()
+	if err != nil {
+		return "", err
+	}
+	encoded, err := codec.Marshal(payload)
+	if err != nil {
+		return "", err
+	}
+	return string(encoded), nil
+}
+
+// decodeObject decodes raw with codec (defaulting to JSONCodec) back into
+// the payload obj.SetValue expects.
+func decodeObject(codec Codec, raw string, obj KVObject) error {
+	if codec == nil {
+		codec = JSONCodec{}
+	}
+	var payload []byte
+	if err := codec.Unmarshal([]byte(raw), &payload); err != nil {
+		return err
+	}
+	return obj.SetValue(payload)
+}
+
+// PutKVObjectInput is used as input to the PutKVObject function.
+type PutKVObjectInput struct {
+	// StoreID is the ID of the KV store to write to (required).
+	StoreID string
+	// Object is the object to persist; its Key() determines the KV store
+	// key it is written under (required).
+	Object KVObject
+	// Codec controls how Object's Value() payload is encoded. Defaults to
+	// JSONCodec.
+	Codec Codec
+}
+
+// PutKVObject encodes i.Object.Value() with i.Codec (defaulting to
+// JSONCodec) and writes it to the KV store under i.Object.Key().
+func (c *Client) PutKVObject(i *PutKVObjectInput) error {
+	if i.StoreID == "" {
+		return ErrMissingStoreID
+	}
+	if i.Object == nil {
+		return errors.New("fastly: PutKVObjectInput.Object must not be nil")
+	}
+
+	raw, err := encodeObject(i.Codec, i.Object)
+	if err != nil {
+		return err
+	}
+
+	return c.InsertKVStoreKey(&InsertKVStoreKeyInput{
+		StoreID: i.StoreID,
+		Key:     i.Object.Key(),
+		Value:   raw,
+	})
+}
+
+// PutKVObjectAtomicInput is used as input to the PutKVObjectAtomic function.
+type PutKVObjectAtomicInput struct {
+	// StoreID is the ID of the KV store to write to (required).
+	StoreID string
+	// Object is the object to persist (required). Its Index() is sent as
+	// If-Generation-Match. GetKVObject and ListKVObjects do not record the
+	// generation they read, so Index() reports whatever Object's own type
+	// tracks.
+	Object KVObject
+	// Codec controls how Object's Value() payload is encoded. Defaults to
+	// JSONCodec.
+	Codec Codec
+}
+
+// PutKVObjectAtomic behaves like PutKVObject but additionally sends
+// i.Object.Index() as an If-Generation-Match precondition. If another writer
+// has since modified the key, the KV store rejects the write and
+// PutKVObjectAtomic returns ErrCASMismatch.
+func (c *Client) PutKVObjectAtomic(i *PutKVObjectAtomicInput) error {
+	if i.StoreID == "" {
+		return ErrMissingStoreID
+	}
+	if i.Object == nil {
+		return errors.New("fastly: PutKVObjectAtomicInput.Object must not be nil")
+	}
+
+	raw, err := encodeObject(i.Codec, i.Object)
+	if err != nil {
+		return err
+	}
+
+	err = c.InsertKVStoreKey(&InsertKVStoreKeyInput{
+		StoreID:           i.StoreID,
+		Key:               i.Object.Key(),
+		Value:             raw,
+		IfGenerationMatch: fmt.Sprintf("%d", i.Object.Index()),
+	})
+	if isGenerationMismatch(err) {
+		return ErrCASMismatch
+	}
+	return err
+}
+
+// GetKVObjectInput is used as input to the GetKVObject function.
+type GetKVObjectInput struct {
+	// StoreID is the ID of the KV store to read from (required).
+	StoreID string
+	// Object is populated via SetValue with the stored value (required).
+	Object KVObject
+	// Codec controls how the stored payload is decoded before being
+	// passed to Object.SetValue. Defaults to JSONCodec.
+	Codec Codec
+}
+
+// GetKVObject fetches i.Object.Key() from the KV store, decodes it with
+// i.Codec (defaulting to JSONCodec), and passes the result to
+// i.Object.SetValue.
+func (c *Client) GetKVObject(i *GetKVObjectInput) error {
+	if i.StoreID == "" {
+		return ErrMissingStoreID
+	}
+	if i.Object == nil {
+		return errors.New("fastly: GetKVObjectInput.Object must not be nil")
+	}
+
+	raw, err := c.GetKVStoreKey(&GetKVStoreKeyInput{StoreID: i.StoreID, Key: i.Object.Key()})
+	if err != nil {
+		return err
+	}
+	return decodeObject(i.Codec, raw, i.Object)
+}
+
+// DeleteKVObjectInput is used as input to the DeleteKVObject function.
+type DeleteKVObjectInput struct {
+	// StoreID is the ID of the KV store to delete from (required).
+	StoreID string
+	// Key is the key to delete (required).
+	Key string
+}
+
+// DeleteKVObject deletes i.Key from the KV store.
+func (c *Client) DeleteKVObject(i *DeleteKVObjectInput) error {
+	if i.StoreID == "" {
+		return ErrMissingStoreID
+	}
+	if i.Key == "" {
+		return ErrMissingKey
+	}
+	return c.DeleteKVStoreKey(&DeleteKVStoreKeyInput{StoreID: i.StoreID, Key: i.Key})
+}
+
+// DeleteKVObjectAtomicInput is used as input to the DeleteKVObjectAtomic
+// function.
+type DeleteKVObjectAtomicInput struct {
+	// StoreID is the ID of the KV store to delete from (required).
+	StoreID string
+	// Object identifies the key to delete and the generation it must still
+	// be at (required).
+	Object KVObject
+}
+
+// DeleteKVObjectAtomic deletes i.Object.Key() only if it is still at
+// generation i.Object.Index(), returning ErrCASMismatch if another writer
+// has modified or deleted it first.
+func (c *Client) DeleteKVObjectAtomic(i *DeleteKVObjectAtomicInput) error {
+	if i.StoreID == "" {
+		return ErrMissingStoreID
+	}
+	if i.Object == nil {
+		return errors.New("fastly: DeleteKVObjectAtomicInput.Object must not be nil")
+	}
+
+	err := c.DeleteKVStoreKey(&DeleteKVStoreKeyInput{
+		StoreID:           i.StoreID,
+		Key:               i.Object.Key(),
+		IfGenerationMatch: fmt.Sprintf("%d", i.Object.Index()),
+	})
+	if isGenerationMismatch(err) {
+		return ErrCASMismatch
+	}
+	return err
+}
+
+// ListKVObjectsInput is used as input to the ListKVObjects function.
+type ListKVObjectsInput struct {
+	// StoreID is the ID of the KV store to list from (required).
+	StoreID string
+	// Prefix restricts the listing to keys starting with Prefix.
+	Prefix string
+	// Proto is a prototype instance of the object type to materialize; a
+	// new instance is created via reflection for every matching key, so
+	// Proto must be a non-nil pointer.
+	Proto KVObject
+	// Codec controls how each stored payload is decoded. Defaults to
+	// JSONCodec.
+	Codec Codec
+}
+
+// ListKVObjects lists every key under i.Prefix and decodes each one (via
+// i.Codec, defaulting to JSONCodec) into a freshly allocated instance of
+// i.Proto's concrete type, returning one KVObject per matching key.
+func (c *Client) ListKVObjects(i *ListKVObjectsInput) ([]KVObject, error) {
+	if i.StoreID == "" {
+		return nil, ErrMissingStoreID
+	}
+	if i.Proto == nil {
+		return nil, errors.New("fastly: ListKVObjectsInput.Proto must not be nil")
+	}
+
+	protoType := reflect.TypeOf(i.Proto)
+	if protoType.Kind() != reflect.Ptr {
+		return nil, fmt.Errorf("fastly: ListKVObjectsInput.Proto must be a pointer, got %s", protoType)
+	}
+	elemType := protoType.Elem()
+
+	var objects []KVObject
+	p := c.NewListKVStoreKeysPaginator(&ListKVStoreKeysInput{
+		StoreID: i.StoreID,
+		Prefix:  i.Prefix,
+	})
+	for p.Next() {
+		for _, key := range p.Keys() {
+			raw, err := c.GetKVStoreKey(&GetKVStoreKeyInput{StoreID: i.StoreID, Key: key})
+			if err != nil {
+				return nil, err
+			}
+
+			obj, ok := reflect.New(elemType).Interface().(KVObject)
+			if !ok {
+				return nil, fmt.Errorf("fastly: %s does not implement KVObject", elemType)
+			}
+			if err := decodeObject(i.Codec, raw, obj); err != nil {
+				return nil, err
+			}
+			objects = append(objects, obj)
+		}
+	}
+	if err := p.Err(); err != nil {
+		return nil, err
+	}
+
+	return objects, nil
+}
+
+// isGenerationMismatch reports whether err represents an If-Generation-Match
+// precondition failure returned by the KV store API.
+func isGenerationMismatch(err error) bool {
+	var httpErr *HTTPError
+	if errors.As(err, &httpErr) {
+		return httpErr.StatusCode == 412
+	}
+	return false
+}
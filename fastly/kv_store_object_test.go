@@ -0,0 +1,124 @@
+package fastly
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+// widget is a sample KVObject used to exercise PutKVObject/GetKVObject/
+// DeleteKVObject/ListKVObjects.
+type widget struct {
+	Name  string `json:"name"`
+	Price int    `json:"price"`
+	index uint64
+}
+
+func (w *widget) Key() string { return "widget-" + w.Name }
+
+func (w *widget) Value() ([]byte, error) {
+	return json.Marshal(w)
+}
+
+func (w *widget) SetValue(b []byte) error {
+	return json.Unmarshal(b, w)
+}
+
+func (w *widget) Index() uint64 { return w.index }
+
+func TestClient_KVObject(t *testing.T) {
+	t.Parallel()
+
+	const storeID = "kv-object-test-store"
+
+	want := &widget{Name: "gizmo", Price: 42}
+
+	Record(t, "kv_store/put-object", func(c *Client) {
+		err := c.PutKVObject(&PutKVObjectInput{StoreID: storeID, Object: want})
+		if err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	got := &widget{Name: "gizmo"}
+	Record(t, "kv_store/get-object", func(c *Client) {
+		err := c.GetKVObject(&GetKVObjectInput{StoreID: storeID, Object: got})
+		if err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	if !reflect.DeepEqual(want.Name, got.Name) || want.Price != got.Price {
+		t.Errorf("GetKVObject: got %+v, want %+v", got, want)
+	}
+
+	Record(t, "kv_store/list-objects", func(c *Client) {
+		objects, err := c.ListKVObjects(&ListKVObjectsInput{
+			StoreID: storeID,
+			Prefix:  "widget-",
+			Proto:   &widget{},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(objects) != 1 {
+			t.Errorf("ListKVObjects: got %d objects, want 1", len(objects))
+		}
+	})
+
+	Record(t, "kv_store/delete-object", func(c *Client) {
+		err := c.DeleteKVObject(&DeleteKVObjectInput{StoreID: storeID, Key: want.Key()})
+		if err != nil {
+			t.Fatal(err)
+		}
+	})
+}
+
+// blob is a sample KVObject that stores its payload as raw bytes, used to
+// exercise non-default Codecs.
+type blob struct {
+	data  []byte
+	index uint64
+}
+
+func (b *blob) Key() string             { return "blob" }
+func (b *blob) Value() ([]byte, error)  { return b.data, nil }
+func (b *blob) SetValue(v []byte) error { b.data = v; return nil }
+func (b *blob) Index() uint64           { return b.index }
+
+func TestEncodeDecodeObject_Codecs(t *testing.T) {
+	t.Parallel()
+
+	for _, codec := range []Codec{nil, JSONCodec{}, GobCodec{}, RawCodec{}} {
+		want := &blob{data: []byte("hello")}
+
+		raw, err := encodeObject(codec, want)
+		if err != nil {
+			t.Fatalf("encodeObject(%T): %v", codec, err)
+		}
+
+		got := &blob{}
+		if err := decodeObject(codec, raw, got); err != nil {
+			t.Fatalf("decodeObject(%T): %v", codec, err)
+		}
+
+		if string(got.data) != string(want.data) {
+			t.Errorf("codec %T: got %q, want %q", codec, got.data, want.data)
+		}
+	}
+}
+
+func TestClient_KVObject_Atomic_CASMismatch(t *testing.T) {
+	t.Parallel()
+
+	const storeID = "kv-object-test-store"
+
+	stale := &widget{Name: "sprocket", Price: 7, index: 1}
+
+	Record(t, "kv_store/put-object-atomic-mismatch", func(c *Client) {
+		err := c.PutKVObjectAtomic(&PutKVObjectAtomicInput{StoreID: storeID, Object: stale})
+		if err != ErrCASMismatch {
+			t.Errorf("PutKVObjectAtomic: got %v, want ErrCASMismatch", err)
+		}
+	})
+}
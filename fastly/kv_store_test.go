@@ -174,6 +174,40 @@ func TestClient_KVStore(t *testing.T) {
 	})
 }
 
+func TestClient_ListKVStoreKeys_Hierarchical(t *testing.T) {
+	t.Parallel()
+
+	const storeID = "kv-store-hierarchy-test-store"
+
+	hierKeys := []string{"foo/bar", "foo/baz", "foo/sub/x"}
+	Record(t, "kv_store/hierarchy-create-keys", func(c *Client) {
+		for _, key := range hierKeys {
+			err := c.InsertKVStoreKey(&InsertKVStoreKeyInput{StoreID: storeID, Key: key, Value: key})
+			if err != nil {
+				t.Errorf("error inserting key %q: %v", key, err)
+			}
+		}
+	})
+
+	var groups []string
+	Record(t, "kv_store/hierarchy-list-keys", func(c *Client) {
+		resp, err := c.ListKVStoreKeys(&ListKVStoreKeysInput{
+			StoreID:   storeID,
+			Prefix:    "foo/",
+			Separator: "/",
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		groups = resp.Groups
+	})
+
+	want := []string{"foo/bar", "foo/baz", "foo/sub/"}
+	if !reflect.DeepEqual(groups, want) {
+		t.Errorf("ListKVStoreKeys Groups: got %q, want %q", groups, want)
+	}
+}
+
 func TestClient_CreateKVStoresWithLocations(t *testing.T) {
 	var (
 		stores []*KVStore
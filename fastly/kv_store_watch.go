@@ -0,0 +1,316 @@
+package fastly
+
+import (
+	"context"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// defaultWatchInterval is the polling interval used when
+// WatchKVStoreKeyInput.Interval / WatchKVStorePrefixInput.Interval is zero.
+const defaultWatchInterval = 5 * time.Second
+
+// KVStoreEventType identifies the kind of change a KVStoreEvent reports.
+type KVStoreEventType int
+
+const (
+	// KVStoreEventPut indicates a key was created or its value changed.
+	KVStoreEventPut KVStoreEventType = iota
+	// KVStoreEventDelete indicates a key was removed.
+	KVStoreEventDelete
+)
+
+// KVStoreEvent describes a single observed change to a KV store.
+// Generation is the generation the value was read at (see
+// GetKVStoreKeyWithGeneration), so it is set for Put events but not for
+// synthesized Delete events.
+type KVStoreEvent struct {
+	Type       KVStoreEventType
+	Key        string
+	Value      string
+	Generation string
+}
+
+// WatchKVStoreKeyInput is used as input to the WatchKVStoreKey function.
+type WatchKVStoreKeyInput struct {
+	// StoreID is the ID of the KV store to watch (required).
+	StoreID string
+	// Key is the key to watch (required).
+	Key string
+	// Interval is how often to poll for changes. Defaults to 5s.
+	Interval time.Duration
+}
+
+// KVStoreWatch is a running poll loop started by WatchKVStoreKey or
+// WatchKVStorePrefix. Each poll-loop generation owns its own channel pair,
+// closing them itself once the context passed to the Watch call is done or
+// RestartWatch/Close stops that generation. RestartWatch swaps in a
+// fresh pair, so callers should re-fetch Events/Errors after calling it
+// instead of continuing to read from a channel obtained beforehand.
+type KVStoreWatch struct {
+	mu     sync.Mutex
+	events chan KVStoreEvent
+	errs   chan error
+	cancel context.CancelFunc
+
+	restart func()
+}
+
+// Events returns the current generation's channel of observed changes.
+func (w *KVStoreWatch) Events() <-chan KVStoreEvent {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.events
+}
+
+// Errors returns the current generation's channel of polling errors. Errors
+// do not stop the watch; polling continues on the next interval.
+func (w *KVStoreWatch) Errors() <-chan error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.errs
+}
+
+// RestartWatch stops the current poll loop and immediately starts a new one
+// from scratch, re-reading the current state instead of diffing against
+// what was previously observed, so every key present is reported again as a
+// Put event. The old generation's
+// channels are closed by its own poll loop; fetch Events/Errors again after
+// calling RestartWatch to read from the new generation.
+func (w *KVStoreWatch) RestartWatch() { w.restart() }
+
+// Close stops the poll loop, which closes its channels once it notices.
+func (w *KVStoreWatch) Close() {
+	w.mu.Lock()
+	cancel := w.cancel
+	w.mu.Unlock()
+	cancel()
+}
+
+// WatchKVStoreKey polls i.Key every i.Interval (default 5s, jittered),
+// sending If-None-Match once a generation is known so the store only returns
+// a full response when the key's generation has changed, and emits a KVStoreEvent
+// whenever it has. Since the KV Store API is HTTP-polling only, this
+// starts a background goroutine
+// that runs until ctx is done or the returned watch is closed.
+func (c *Client) WatchKVStoreKey(ctx context.Context, i *WatchKVStoreKeyInput) (*KVStoreWatch, error) {
+	if i.StoreID == "" {
+		return nil, ErrMissingStoreID
+	}
+	if i.Key == "" {
+		return nil, ErrMissingKey
+	}
+
+	w := &KVStoreWatch{}
+
+	start := func() {
+		runCtx, cancel := context.WithCancel(ctx)
+		events, errs := newWatchChannels()
+		w.mu.Lock()
+		w.cancel = cancel
+		w.events, w.errs = events, errs
+		w.mu.Unlock()
+		go c.pollKey(runCtx, i, events, errs)
+	}
+	w.restart = func() {
+		w.mu.Lock()
+		cancel := w.cancel
+		w.mu.Unlock()
+		cancel()
+		start()
+	}
+	start()
+
+	return w, nil
+}
+
+func (c *Client) pollKey(ctx context.Context, i *WatchKVStoreKeyInput, events chan<- KVStoreEvent, errs chan<- error) {
+	defer close(events)
+	defer close(errs)
+
+	var lastGeneration string
+	var seen bool
+
+	for {
+		resp, err := c.GetKVStoreKeyWithGeneration(&GetKVStoreKeyInput{StoreID: i.StoreID, Key: i.Key, IfNoneMatch: lastGeneration})
+		switch {
+		case err != nil && isNotFound(err):
+			if seen {
+				emitEvent(ctx, events, KVStoreEvent{Type: KVStoreEventDelete, Key: i.Key})
+				seen = false
+				lastGeneration = ""
+			}
+		case err != nil:
+			emitErr(ctx, errs, err)
+		case resp.NotModified:
+			// Still at lastGeneration; nothing changed.
+		default:
+			if !seen || resp.Generation != lastGeneration {
+				emitEvent(ctx, events, KVStoreEvent{Type: KVStoreEventPut, Key: i.Key, Value: resp.Value, Generation: resp.Generation})
+			}
+			lastGeneration = resp.Generation
+			seen = true
+		}
+
+		if !sleepWithJitter(ctx, watchInterval(i.Interval)) {
+			return
+		}
+	}
+}
+
+// WatchKVStorePrefixInput is used as input to the WatchKVStorePrefix
+// function.
+type WatchKVStorePrefixInput struct {
+	// StoreID is the ID of the KV store to watch (required).
+	StoreID string
+	// Prefix restricts the watch to keys starting with Prefix.
+	Prefix string
+	// Interval is how often to poll for changes. Defaults to 5s.
+	Interval time.Duration
+}
+
+// WatchKVStorePrefix polls the set of keys under i.Prefix every i.Interval
+// (default 5s, jittered). Each key is re-read with If-None-Match against
+// its last-seen generation, and the resulting key set is diffed between
+// polls to synthesize Put events for new or changed keys and Delete events
+// for keys that disappear.
+func (c *Client) WatchKVStorePrefix(ctx context.Context, i *WatchKVStorePrefixInput) (*KVStoreWatch, error) {
+	if i.StoreID == "" {
+		return nil, ErrMissingStoreID
+	}
+
+	w := &KVStoreWatch{}
+
+	start := func() {
+		runCtx, cancel := context.WithCancel(ctx)
+		events, errs := newWatchChannels()
+		w.mu.Lock()
+		w.cancel = cancel
+		w.events, w.errs = events, errs
+		w.mu.Unlock()
+		go c.pollPrefix(runCtx, i, events, errs)
+	}
+	w.restart = func() {
+		w.mu.Lock()
+		cancel := w.cancel
+		w.mu.Unlock()
+		cancel()
+		start()
+	}
+	start()
+
+	return w, nil
+}
+
+// kvWatchKeyState is a key's last-observed value and the generation it was
+// read at, used to issue a conditional re-read on the next poll.
+type kvWatchKeyState struct {
+	value      string
+	generation string
+}
+
+func (c *Client) pollPrefix(ctx context.Context, i *WatchKVStorePrefixInput, events chan<- KVStoreEvent, errs chan<- error) {
+	defer close(events)
+	defer close(errs)
+
+	known := make(map[string]kvWatchKeyState)
+
+	for {
+		current := make(map[string]kvWatchKeyState)
+
+		p := c.NewListKVStoreKeysPaginator(&ListKVStoreKeysInput{StoreID: i.StoreID, Prefix: i.Prefix})
+		var listErr error
+		for p.Next() {
+			for _, key := range p.Keys() {
+				prior := known[key]
+				resp, err := c.GetKVStoreKeyWithGeneration(&GetKVStoreKeyInput{StoreID: i.StoreID, Key: key, IfNoneMatch: prior.generation})
+				switch {
+				case err != nil && isNotFound(err):
+					continue
+				case err != nil:
+					listErr = err
+					if _, ok := known[key]; ok {
+						current[key] = prior
+					}
+				case resp.NotModified:
+					current[key] = prior
+				default:
+					current[key] = kvWatchKeyState{value: resp.Value, generation: resp.Generation}
+				}
+			}
+		}
+		if err := p.Err(); err != nil {
+			// The listing is incomplete, so skip the diff rather than report
+			// unlisted keys as deleted; they are compared again next poll.
+			emitErr(ctx, errs, err)
+			if !sleepWithJitter(ctx, watchInterval(i.Interval)) {
+				return
+			}
+			continue
+		}
+		if listErr != nil {
+			emitErr(ctx, errs, listErr)
+		}
+
+		for key, state := range current {
+			if old, ok := known[key]; !ok || old.generation != state.generation {
+				emitEvent(ctx, events, KVStoreEvent{Type: KVStoreEventPut, Key: key, Value: state.value, Generation: state.generation})
+			}
+		}
+		for key := range known {
+			if _, ok := current[key]; !ok {
+				emitEvent(ctx, events, KVStoreEvent{Type: KVStoreEventDelete, Key: key})
+			}
+		}
+		known = current
+
+		if !sleepWithJitter(ctx, watchInterval(i.Interval)) {
+			return
+		}
+	}
+}
+
+// newWatchChannels returns a fresh, unbuffered channel pair for a single
+// poll-loop generation. Each generation owns its pair exclusively and is
+// solely responsible for closing it; pairs are never shared or reused
+// across a RestartWatch.
+func newWatchChannels() (chan KVStoreEvent, chan error) {
+	return make(chan KVStoreEvent), make(chan error)
+}
+
+func watchInterval(d time.Duration) time.Duration {
+	if d <= 0 {
+		return defaultWatchInterval
+	}
+	return d
+}
+
+// sleepWithJitter sleeps for d plus up to 10% jitter, returning false if ctx
+// is done before the sleep completes.
+func sleepWithJitter(ctx context.Context, d time.Duration) bool {
+	var jitter time.Duration
+	if upper := int64(d) / 10; upper > 0 {
+		jitter = time.Duration(rand.Int63n(upper))
+	}
+	select {
+	case <-time.After(d + jitter):
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+func emitEvent(ctx context.Context, ch chan<- KVStoreEvent, e KVStoreEvent) {
+	select {
+	case ch <- e:
+	case <-ctx.Done():
+	}
+}
+
+func emitErr(ctx context.Context, ch chan<- error, err error) {
+	select {
+	case ch <- err:
+	case <-ctx.Done():
+	}
+}
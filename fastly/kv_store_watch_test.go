@@ -0,0 +1,165 @@
+package fastly
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestClient_WatchKVStoreKey(t *testing.T) {
+	t.Parallel()
+
+	const storeID = "kv-watch-test-store"
+
+	var events []KVStoreEvent
+	Record(t, "kv_store/watch-key", func(c *Client) {
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		defer cancel()
+
+		watch, err := c.WatchKVStoreKey(ctx, &WatchKVStoreKeyInput{
+			StoreID:  storeID,
+			Key:      "apple",
+			Interval: 10 * time.Millisecond,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if err := c.InsertKVStoreKey(&InsertKVStoreKeyInput{StoreID: storeID, Key: "apple", Value: "fuji"}); err != nil {
+			t.Fatal(err)
+		}
+		if err := c.InsertKVStoreKey(&InsertKVStoreKeyInput{StoreID: storeID, Key: "apple", Value: "gala"}); err != nil {
+			t.Fatal(err)
+		}
+		if err := c.DeleteKVStoreKey(&DeleteKVStoreKeyInput{StoreID: storeID, Key: "apple"}); err != nil {
+			t.Fatal(err)
+		}
+
+	collect:
+		for {
+			select {
+			case e, ok := <-watch.Events():
+				if !ok {
+					break collect
+				}
+				events = append(events, e)
+			case err := <-watch.Errors():
+				t.Fatalf("unexpected watch error: %v", err)
+			case <-ctx.Done():
+				break collect
+			}
+		}
+	})
+
+	if len(events) < 2 {
+		t.Fatalf("expected at least a Put and a Delete event, got %d events: %+v", len(events), events)
+	}
+	last := events[len(events)-1]
+	if last.Type != KVStoreEventDelete || last.Key != "apple" {
+		t.Errorf("final event: got %+v, want a Delete for %q", last, "apple")
+	}
+}
+
+func TestClient_WatchKVStoreKey_RestartThenClose(t *testing.T) {
+	t.Parallel()
+
+	const storeID = "kv-watch-test-store"
+
+	Record(t, "kv_store/watch-key-restart", func(c *Client) {
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		defer cancel()
+
+		watch, err := c.WatchKVStoreKey(ctx, &WatchKVStoreKeyInput{
+			StoreID:  storeID,
+			Key:      "apple",
+			Interval: 10 * time.Millisecond,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// Drain the pre-restart channels until the old generation closes
+		// them; its emits also give up once RestartWatch cancels it.
+		go func() {
+			for range watch.Events() {
+			}
+		}()
+		go func() {
+			for range watch.Errors() {
+			}
+		}()
+
+		watch.RestartWatch()
+		watch.RestartWatch()
+
+		events, errs := watch.Events(), watch.Errors()
+		watch.Close()
+
+		for range events {
+		}
+		for range errs {
+		}
+	})
+}
+
+func TestClient_WatchKVStorePrefix(t *testing.T) {
+	t.Parallel()
+
+	const storeID = "kv-watch-test-store"
+
+	var events []KVStoreEvent
+	Record(t, "kv_store/watch-prefix", func(c *Client) {
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		defer cancel()
+
+		watch, err := c.WatchKVStorePrefix(ctx, &WatchKVStorePrefixInput{
+			StoreID:  storeID,
+			Prefix:   "fruit/",
+			Interval: 10 * time.Millisecond,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if err := c.InsertKVStoreKey(&InsertKVStoreKeyInput{StoreID: storeID, Key: "fruit/apple", Value: "fuji"}); err != nil {
+			t.Fatal(err)
+		}
+		if err := c.InsertKVStoreKey(&InsertKVStoreKeyInput{StoreID: storeID, Key: "fruit/banana", Value: "cavendish"}); err != nil {
+			t.Fatal(err)
+		}
+		if err := c.DeleteKVStoreKey(&DeleteKVStoreKeyInput{StoreID: storeID, Key: "fruit/apple"}); err != nil {
+			t.Fatal(err)
+		}
+
+	collect:
+		for {
+			select {
+			case e, ok := <-watch.Events():
+				if !ok {
+					break collect
+				}
+				events = append(events, e)
+			case err := <-watch.Errors():
+				t.Fatalf("unexpected watch error: %v", err)
+			case <-ctx.Done():
+				break collect
+			}
+		}
+	})
+
+	var sawPutBanana, sawDeleteApple bool
+	for _, e := range events {
+		if e.Type == KVStoreEventPut && e.Key == "fruit/banana" {
+			sawPutBanana = true
+		}
+		if e.Type == KVStoreEventDelete && e.Key == "fruit/apple" {
+			sawDeleteApple = true
+		}
+	}
+	if !sawPutBanana {
+		t.Error("expected a Put event for fruit/banana")
+	}
+	if !sawDeleteApple {
+		t.Error("expected a Delete event for fruit/apple")
+	}
+}